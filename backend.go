@@ -0,0 +1,133 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Backend computes the Status of a Git working tree. The default
+// implementation, execBackend, shells out to the git binary; GoGitBackend
+// implements it on top of go-git for environments where git isn't available
+// on PATH.
+type Backend interface {
+	// Status returns the Status of the Git working tree 'dir'.
+	Status(ctx context.Context, dir string) (*Status, error)
+}
+
+// execBackend is a Backend that shells out to the git binary.
+type execBackend struct{}
+
+// Status implements Backend.
+func (execBackend) Status(ctx context.Context, dir string) (*Status, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "-uall", "--porcelain=2", "--branch", "-z", dir)
+	cmd.Env = append(cmd.Env, "LC_ALL=C")
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't run git status")
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't run git status")
+	}
+
+	st := &Status{}
+	err = st.parsePorcelain(out)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse git status")
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't run git status")
+	}
+
+	gitDir, err := gitDir(ctx, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't resolve git dir")
+	}
+	st.detectState(gitDir)
+
+	return st, nil
+}
+
+// gitDir resolves the path to the repository's git directory for dir,
+// respecting the '.git' files used by linked worktrees, by walking up from
+// dir and reading '.git' directly instead of shelling out to git a second
+// time. GIT_DIR, if set, takes priority, matching git's own precedence.
+func gitDir(ctx context.Context, dir string) (string, error) {
+	if d := os.Getenv("GIT_DIR"); d != "" {
+		if !filepath.IsAbs(d) {
+			d = filepath.Join(dir, d)
+		}
+		return d, nil
+	}
+
+	d, err := filepath.Abs(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "can't resolve absolute path")
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		if isBareGitDir(d) {
+			return d, nil
+		}
+
+		p := filepath.Join(d, ".git")
+		fi, err := os.Stat(p)
+		if err == nil {
+			if fi.IsDir() {
+				return p, nil
+			}
+			return resolveGitFile(p)
+		}
+		if !os.IsNotExist(err) {
+			return "", errors.Wrap(err, "can't stat .git")
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			return "", fmt.Errorf("not a git repository (or any of the parent directories): %s", dir)
+		}
+		d = parent
+	}
+}
+
+// isBareGitDir reports wether d is itself a git directory (a bare repository,
+// or one already resolved via GIT_DIR), recognized by the presence of a HEAD
+// file and an objects directory.
+func isBareGitDir(d string) bool {
+	return isFile(filepath.Join(d, "HEAD")) && isDir(filepath.Join(d, "objects"))
+}
+
+// resolveGitFile reads the 'gitdir: <path>' line of a '.git' file, as used by
+// linked worktrees and submodules, and resolves it to an absolute path.
+func resolveGitFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrap(err, "can't read .git file")
+	}
+
+	line := strings.TrimSpace(string(b))
+	const prefix = "gitdir: "
+	if !strings.HasPrefix(line, prefix) {
+		return "", fmt.Errorf("malformed .git file: %q", path)
+	}
+
+	d := strings.TrimPrefix(line, prefix)
+	if !filepath.IsAbs(d) {
+		d = filepath.Join(filepath.Dir(path), d)
+	}
+	return d, nil
+}
@@ -0,0 +1,198 @@
+package gitstatus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/pkg/errors"
+)
+
+// GoGitBackend is a Backend that relies on go-git instead of shelling out to
+// the git binary, so that gitstatus works in environments (containers,
+// sandboxes, embedded tools) where git isn't available on PATH.
+type GoGitBackend struct{}
+
+// Status implements Backend.
+func (GoGitBackend) Status(ctx context.Context, dir string) (*Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't open repository")
+	}
+
+	st := &Status{}
+
+	if err := st.fillBranch(ctx, repo); err != nil {
+		return nil, err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't open worktree")
+	}
+
+	wtStatus, err := wt.Status()
+	if err != nil {
+		return nil, errors.Wrap(err, "can't compute worktree status")
+	}
+
+	for path, s := range wtStatus {
+		st.fillFileStatus(path, s)
+	}
+
+	if fss, ok := repo.Storer.(*filesystem.Storage); ok {
+		st.detectState(fss.Filesystem().Root())
+	}
+
+	return st, ctx.Err()
+}
+
+// fillBranch fills the branch, commit and ahead/behind fields of st from
+// repo's HEAD and its upstream, if any.
+func (st *Status) fillBranch(ctx context.Context, repo *git.Repository) error {
+	head, err := repo.Head()
+	if err != nil {
+		if err == plumbing.ErrReferenceNotFound {
+			st.IsInitial = true
+
+			// HEAD is an unborn symref, e.g. "ref: refs/heads/master" with no
+			// commit behind it yet: resolve the branch name from the symref
+			// target directly, since repo.Head() refuses to.
+			if symref, err := repo.Reference(plumbing.HEAD, false); err == nil && symref.Type() == plumbing.SymbolicReference {
+				st.LocalBranch = symref.Target().Short()
+			}
+			return nil
+		}
+		return errors.Wrap(err, "can't resolve HEAD")
+	}
+
+	st.CommitSHA1 = head.Hash().String()
+
+	if !head.Name().IsBranch() {
+		st.IsDetached = true
+		return nil
+	}
+	st.LocalBranch = head.Name().Short()
+
+	cfg, err := repo.Config()
+	if err != nil {
+		return errors.Wrap(err, "can't read repository config")
+	}
+
+	branchCfg, ok := cfg.Branches[st.LocalBranch]
+	if !ok || branchCfg.Merge == "" {
+		return nil
+	}
+	st.RemoteBranch = fmt.Sprintf("%s/%s", branchCfg.Remote, branchCfg.Merge.Short())
+
+	upstream, err := repo.Reference(plumbing.NewRemoteReferenceName(branchCfg.Remote, branchCfg.Merge.Short()), true)
+	if err != nil {
+		// No upstream ref fetched locally yet; leave ahead/behind at zero.
+		return nil
+	}
+
+	ahead, behind, err := countAheadBehind(ctx, repo, head.Hash(), upstream.Hash())
+	if err != nil {
+		return err
+	}
+	st.AheadCount = ahead
+	st.BehindCount = behind
+
+	return nil
+}
+
+// countAheadBehind returns how many commits reachable from head aren't
+// reachable from upstream (ahead), and vice-versa (behind).
+func countAheadBehind(ctx context.Context, repo *git.Repository, head, upstream plumbing.Hash) (ahead, behind int, err error) {
+	if head == upstream {
+		return 0, 0, nil
+	}
+
+	headOnly, err := commitsNotIn(ctx, repo, head, upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	upstreamOnly, err := commitsNotIn(ctx, repo, upstream, head)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return len(headOnly), len(upstreamOnly), nil
+}
+
+// commitsNotIn returns the set of commit hashes reachable from 'from' that
+// aren't reachable from 'excluding'. The walk is aborted as soon as ctx is
+// cancelled.
+func commitsNotIn(ctx context.Context, repo *git.Repository, from, excluding plumbing.Hash) (map[plumbing.Hash]struct{}, error) {
+	excluded := map[plumbing.Hash]struct{}{}
+	excludeIter, err := repo.Log(&git.LogOptions{From: excluding})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't walk commit history")
+	}
+	err = excludeIter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		excluded[c.Hash] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't walk commit history")
+	}
+
+	result := map[plumbing.Hash]struct{}{}
+	fromIter, err := repo.Log(&git.LogOptions{From: from})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't walk commit history")
+	}
+	err = fromIter.ForEach(func(c *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := excluded[c.Hash]; !ok {
+			result[c.Hash] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't walk commit history")
+	}
+
+	return result, nil
+}
+
+// fillFileStatus updates the relevant counters and slices of st from a single
+// go-git worktree file status entry.
+func (st *Status) fillFileStatus(path string, s *git.FileStatus) {
+	if s.Staging == git.Untracked && s.Worktree == git.Untracked {
+		st.Untracked = append(st.Untracked, path)
+		st.NumUntracked++
+		return
+	}
+
+	if s.Staging == git.UpdatedButUnmerged || s.Worktree == git.UpdatedButUnmerged {
+		st.NumConflicts++
+		st.Unmerged = append(st.Unmerged, Entry{Path: path})
+		return
+	}
+
+	entry := Entry{Path: path, OrigPath: s.Extra}
+
+	if s.Staging == git.Renamed || s.Staging == git.Copied {
+		st.NumRenamed++
+		st.Renamed = append(st.Renamed, entry)
+	} else {
+		st.Changed = append(st.Changed, entry)
+	}
+
+	st.countIndexStatus(byte(s.Staging))
+	st.countWorktreeStatus(byte(s.Worktree))
+}
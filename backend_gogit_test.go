@@ -0,0 +1,95 @@
+package gitstatus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSignature = &object.Signature{
+	Name:  "Test",
+	Email: "test@example.com",
+	When:  time.Unix(0, 0),
+}
+
+func initTestRepo(t *testing.T) (dir string, repo *git.Repository) {
+	t.Helper()
+	dir = t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	return dir, repo
+}
+
+func commitFile(t *testing.T, repo *git.Repository, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add(name)
+	require.NoError(t, err)
+	_, err = wt.Commit("add "+name, &git.CommitOptions{Author: testSignature})
+	require.NoError(t, err)
+}
+
+func TestGoGitBackendStatusCleanTree(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	commitFile(t, repo, dir, "a.txt", "hello\n")
+
+	st, err := GoGitBackend{}.Status(context.Background(), dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "master", st.LocalBranch)
+	assert.NotEmpty(t, st.CommitSHA1)
+	assert.False(t, st.IsInitial)
+	assert.Zero(t, st.NumUntracked)
+	assert.Empty(t, st.Changed)
+}
+
+func TestGoGitBackendStatusUntrackedFile(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	commitFile(t, repo, dir, "a.txt", "hello\n")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "untracked.txt"), []byte("new\n"), 0o644))
+
+	st, err := GoGitBackend{}.Status(context.Background(), dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, st.NumUntracked)
+	assert.Equal(t, []string{"untracked.txt"}, st.Untracked)
+}
+
+func TestGoGitBackendStatusStagedChange(t *testing.T) {
+	dir, repo := initTestRepo(t)
+	commitFile(t, repo, dir, "a.txt", "hello\n")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("new\n"), 0o644))
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = wt.Add("b.txt")
+	require.NoError(t, err)
+
+	st, err := GoGitBackend{}.Status(context.Background(), dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, st.NumAdded)
+	require.Len(t, st.Changed, 1)
+	assert.Equal(t, "b.txt", st.Changed[0].Path)
+}
+
+func TestGoGitBackendStatusUnbornHEAD(t *testing.T) {
+	dir, _ := initTestRepo(t)
+
+	st, err := GoGitBackend{}.Status(context.Background(), dir)
+	require.NoError(t, err)
+
+	assert.True(t, st.IsInitial)
+	assert.Equal(t, "master", st.LocalBranch)
+}
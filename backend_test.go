@@ -0,0 +1,101 @@
+package gitstatus
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitDirPlainRepo(t *testing.T) {
+	dir := t.TempDir()
+	gd := filepath.Join(dir, ".git")
+	require.NoError(t, os.MkdirAll(gd, 0o755))
+
+	got, err := gitDir(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, gd, got)
+}
+
+func TestGitDirFromSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	gd := filepath.Join(dir, ".git")
+	require.NoError(t, os.MkdirAll(gd, 0o755))
+	sub := filepath.Join(dir, "a", "b")
+	require.NoError(t, os.MkdirAll(sub, 0o755))
+
+	got, err := gitDir(context.Background(), sub)
+	require.NoError(t, err)
+	assert.Equal(t, gd, got)
+}
+
+func TestGitDirLinkedWorktree(t *testing.T) {
+	dir := t.TempDir()
+	realGitDir := filepath.Join(t.TempDir(), "worktrees", "feature")
+	require.NoError(t, os.MkdirAll(realGitDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git"), []byte("gitdir: "+realGitDir+"\n"), 0o644))
+
+	got, err := gitDir(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, realGitDir, got)
+}
+
+func TestGitDirLinkedWorktreeRelative(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub", ".git-real"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", ".git"), []byte("gitdir: ../.git-real\n"), 0o644))
+
+	got, err := gitDir(context.Background(), filepath.Join(dir, "sub"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".git-real"), got)
+}
+
+func TestGitDirMalformedGitFile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git"), []byte("not a gitdir line\n"), 0o644))
+
+	_, err := gitDir(context.Background(), dir)
+	assert.Error(t, err)
+}
+
+func TestGitDirBareRepo(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "HEAD"), []byte("ref: refs/heads/master\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "objects"), 0o755))
+
+	got, err := gitDir(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, got)
+}
+
+func TestGitDirGitDirEnv(t *testing.T) {
+	dir := t.TempDir()
+	gd := filepath.Join(t.TempDir(), "elsewhere.git")
+	require.NoError(t, os.MkdirAll(gd, 0o755))
+
+	t.Setenv("GIT_DIR", gd)
+
+	got, err := gitDir(context.Background(), dir)
+	require.NoError(t, err)
+	assert.Equal(t, gd, got)
+}
+
+func TestGitDirNotARepo(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := gitDir(context.Background(), dir)
+	assert.Error(t, err)
+}
+
+func TestGitDirCancelledContext(t *testing.T) {
+	dir := t.TempDir()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := gitDir(ctx, dir)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,229 @@
+package gitstatus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prompt formats st using format, a small printf-like mini-language tailored
+// for shell prompts (bash, zsh, tmux status lines):
+//
+//	%b  local branch name
+//	%a  ahead count
+//	%k  behind count
+//	%u  untracked file count
+//	%m  modified or deleted (unstaged) file count
+//	%s  staged (added, deleted, updated or renamed) file count
+//	%c  conflict count
+//	%o  current operation, e.g. "REBASE 2/7" (see Status.operation)
+//	%%  a literal '%'
+//
+// %{...%} delimits a conditional group: the text between %{ and %} is
+// rendered only if at least one of the counter verbs (%a %k %u %m %s %c) it
+// contains is non-zero. This lets a format like "%{ +%a%}%{ -%k%}" only show
+// the ahead/behind indicators when they're relevant.
+//
+// Prompt returns an error if format contains an unknown verb or an
+// unterminated conditional group.
+func (st *Status) Prompt(format string) (string, error) {
+	var buf bytes.Buffer
+	i := 0
+	for i < len(format) {
+		c := format[i]
+		if c != '%' {
+			buf.WriteByte(c)
+			i++
+			continue
+		}
+
+		if i+1 >= len(format) {
+			return "", fmt.Errorf("gitstatus: dangling %% at end of format")
+		}
+
+		switch format[i+1] {
+		case '%':
+			buf.WriteByte('%')
+			i += 2
+		case '{':
+			end := strings.Index(format[i:], "%}")
+			if end < 0 {
+				return "", fmt.Errorf("gitstatus: unterminated %%{ group in format %q", format)
+			}
+			group := format[i+2 : i+end]
+			rendered, err := st.Prompt(group)
+			if err != nil {
+				return "", err
+			}
+			if st.promptGroupActive(group) {
+				buf.WriteString(rendered)
+			}
+			i += end + 2
+		default:
+			v, err := st.promptVerb(format[i+1])
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(v)
+			i += 2
+		}
+	}
+	return buf.String(), nil
+}
+
+// promptVerb returns the rendered value of a single format verb.
+func (st *Status) promptVerb(v byte) (string, error) {
+	switch v {
+	case 'b':
+		return st.LocalBranch, nil
+	case 'a':
+		return strconv.Itoa(st.AheadCount), nil
+	case 'k':
+		return strconv.Itoa(st.BehindCount), nil
+	case 'u':
+		return strconv.Itoa(st.NumUntracked), nil
+	case 'm':
+		return strconv.Itoa(st.numModified()), nil
+	case 's':
+		return strconv.Itoa(st.numStaged()), nil
+	case 'c':
+		return strconv.Itoa(st.NumConflicts), nil
+	case 'o':
+		return st.operation(), nil
+	default:
+		return "", fmt.Errorf("gitstatus: unknown format verb %%%c", v)
+	}
+}
+
+// promptGroupActive reports wether group contains at least one counter verb
+// (%a %k %u %m %s %c) whose value is non-zero.
+func (st *Status) promptGroupActive(group string) bool {
+	for i := 0; i < len(group)-1; i++ {
+		if group[i] != '%' {
+			continue
+		}
+		switch group[i+1] {
+		case 'a':
+			if st.AheadCount != 0 {
+				return true
+			}
+		case 'k':
+			if st.BehindCount != 0 {
+				return true
+			}
+		case 'u':
+			if st.NumUntracked != 0 {
+				return true
+			}
+		case 'm':
+			if st.numModified() != 0 {
+				return true
+			}
+		case 's':
+			if st.numStaged() != 0 {
+				return true
+			}
+		case 'c':
+			if st.NumConflicts != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// numModified returns the number of files modified or deleted in the
+// worktree but not staged, for the %m format verb.
+func (st *Status) numModified() int {
+	return st.NumWorktreeModified + st.NumWorktreeDeleted
+}
+
+// numStaged returns the number of files added, deleted, updated, renamed or
+// copied in the index, for the %s format verb.
+func (st *Status) numStaged() int {
+	return st.NumAdded + st.NumDeleted + st.NumUpdated + st.NumRenamed
+}
+
+// operation returns a short label for the operation currently in progress in
+// the working tree, or "" if none is.
+func (st *Status) operation() string {
+	switch {
+	case st.IsRebasing:
+		if st.RebaseTotal > 0 {
+			return fmt.Sprintf("REBASE %d/%d", st.RebaseStep, st.RebaseTotal)
+		}
+		return "REBASE"
+	case st.IsMerging:
+		return "MERGE"
+	case st.IsCherryPicking:
+		return "CHERRY-PICK"
+	case st.IsReverting:
+		return "REVERT"
+	case st.IsBisecting:
+		return "BISECT"
+	default:
+		return ""
+	}
+}
+
+// jsonStatus holds the part of Status's data encoded by MarshalJSON, adding a
+// pre-computed Operation string (see the %o verb of Prompt) so that
+// consumers, such as editor status lines, don't need to reimplement that
+// logic.
+type jsonStatus struct {
+	NumAdded     int `json:"numAdded"`
+	NumDeleted   int `json:"numDeleted"`
+	NumUpdated   int `json:"numUpdated"`
+	NumRenamed   int `json:"numRenamed"`
+	NumConflicts int `json:"numConflicts"`
+	NumUntracked int `json:"numUntracked"`
+
+	CommitSHA1   string `json:"commitSHA1,omitempty"`
+	LocalBranch  string `json:"localBranch,omitempty"`
+	RemoteBranch string `json:"remoteBranch,omitempty"`
+	AheadCount   int    `json:"aheadCount"`
+	BehindCount  int    `json:"behindCount"`
+
+	IsInitial  bool   `json:"isInitial,omitempty"`
+	IsDetached bool   `json:"isDetached,omitempty"`
+	Operation  string `json:"operation,omitempty"`
+
+	Untracked []string `json:"untracked,omitempty"`
+	Ignored   []string `json:"ignored,omitempty"`
+	Changed   []Entry  `json:"changed,omitempty"`
+	Renamed   []Entry  `json:"renamed,omitempty"`
+	Unmerged  []Entry  `json:"unmerged,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. It encodes the staged, conflict and
+// untracked counters, the branch fields, the path lists and the entries of
+// st under lowerCamelCase keys, and adds a pre-computed "operation" string
+// (see the %o verb of Prompt), so editor integrations can render a status
+// line without reimplementing that logic. The in-progress operation flags
+// and rebase progress are encoded only through "operation"; IsRebased,
+// NumWorktreeDeleted and NumWorktreeModified are not encoded.
+func (st *Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonStatus{
+		NumAdded:     st.NumAdded,
+		NumDeleted:   st.NumDeleted,
+		NumUpdated:   st.NumUpdated,
+		NumRenamed:   st.NumRenamed,
+		NumConflicts: st.NumConflicts,
+		NumUntracked: st.NumUntracked,
+		CommitSHA1:   st.CommitSHA1,
+		LocalBranch:  st.LocalBranch,
+		RemoteBranch: st.RemoteBranch,
+		AheadCount:   st.AheadCount,
+		BehindCount:  st.BehindCount,
+		IsInitial:    st.IsInitial,
+		IsDetached:   st.IsDetached,
+		Operation:    st.operation(),
+		Untracked:    st.Untracked,
+		Ignored:      st.Ignored,
+		Changed:      st.Changed,
+		Renamed:      st.Renamed,
+		Unmerged:     st.Unmerged,
+	})
+}
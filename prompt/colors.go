@@ -0,0 +1,31 @@
+// Package prompt provides small ANSI color helpers for rendering
+// gitstatus.Status.Prompt output in shell prompts and tmux status lines.
+package prompt
+
+import "fmt"
+
+// Color is an ANSI foreground color code.
+type Color int
+
+// Standard ANSI foreground colors.
+const (
+	Black Color = iota + 30
+	Red
+	Green
+	Yellow
+	Blue
+	Magenta
+	Cyan
+	White
+)
+
+// Colorize wraps s in the ANSI escape sequence for c, resetting afterwards.
+func Colorize(c Color, s string) string {
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", int(c), s)
+}
+
+// Bold wraps s in the ANSI escape sequence for bold text, resetting
+// afterwards.
+func Bold(s string) string {
+	return fmt.Sprintf("\x1b[1m%s\x1b[0m", s)
+}
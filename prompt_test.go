@@ -0,0 +1,57 @@
+package gitstatus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusPrompt(t *testing.T) {
+	st := &Status{
+		LocalBranch:         "master",
+		AheadCount:          2,
+		NumUntracked:        3,
+		NumWorktreeModified: 1,
+		NumAdded:            1,
+	}
+
+	got, err := st.Prompt(`%b%{ +%a%}%{ -%k%} u:%u m:%m s:%s`)
+	assert.NoError(t, err)
+	assert.Equal(t, "master +2 u:3 m:1 s:1", got)
+}
+
+func TestStatusPromptGroupHiddenWhenZero(t *testing.T) {
+	st := &Status{LocalBranch: "master"}
+
+	got, err := st.Prompt(`%b%{ ahead %a behind %k%}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "master", got)
+}
+
+func TestStatusPromptOperation(t *testing.T) {
+	st := &Status{IsRebasing: true, RebaseStep: 2, RebaseTotal: 7}
+	got, err := st.Prompt("%o")
+	assert.NoError(t, err)
+	assert.Equal(t, "REBASE 2/7", got)
+}
+
+func TestStatusPromptLiteralPercent(t *testing.T) {
+	got, err := (&Status{}).Prompt("100%%")
+	assert.NoError(t, err)
+	assert.Equal(t, "100%", got)
+}
+
+func TestStatusPromptUnknownVerb(t *testing.T) {
+	_, err := (&Status{}).Prompt("%z")
+	assert.Error(t, err)
+}
+
+func TestStatusPromptUnterminatedGroup(t *testing.T) {
+	_, err := (&Status{}).Prompt("%{unterminated")
+	assert.Error(t, err)
+}
+
+func TestStatusPromptDanglingPercent(t *testing.T) {
+	_, err := (&Status{}).Prompt("abc%")
+	assert.Error(t, err)
+}
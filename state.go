@@ -0,0 +1,70 @@
+package gitstatus
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// detectState stats gitDir, the repository's git directory, for the marker
+// files Git itself uses to track an in-progress rebase, merge, cherry-pick,
+// revert or bisect, and fills the corresponding fields of st.
+func (st *Status) detectState(gitDir string) {
+	switch {
+	case isDir(filepath.Join(gitDir, "rebase-merge")):
+		st.IsRebasing = true
+		st.RebaseStep, st.RebaseTotal = readRebaseMergeProgress(filepath.Join(gitDir, "rebase-merge"))
+	case isDir(filepath.Join(gitDir, "rebase-apply")):
+		st.IsRebasing = true
+		st.RebaseStep, st.RebaseTotal = readRebaseApplyProgress(filepath.Join(gitDir, "rebase-apply"))
+	}
+	st.IsRebased = st.IsRebasing
+
+	st.IsMerging = isFile(filepath.Join(gitDir, "MERGE_HEAD"))
+	st.IsCherryPicking = isFile(filepath.Join(gitDir, "CHERRY_PICK_HEAD"))
+	st.IsReverting = isFile(filepath.Join(gitDir, "REVERT_HEAD"))
+	st.IsBisecting = isFile(filepath.Join(gitDir, "BISECT_LOG"))
+}
+
+// readRebaseMergeProgress reads the msgnum/end files Git maintains in a
+// rebase-merge directory (an interactive, or --merge, rebase) to report the
+// current step and the total number of steps of an in-progress rebase.
+func readRebaseMergeProgress(rebaseDir string) (step, total int) {
+	return readIntFile(filepath.Join(rebaseDir, "msgnum")), readIntFile(filepath.Join(rebaseDir, "end"))
+}
+
+// readRebaseApplyProgress reads the next/last files Git maintains in a
+// rebase-apply directory (an am-based rebase) to report the current step and
+// the total number of steps of an in-progress rebase.
+func readRebaseApplyProgress(rebaseDir string) (step, total int) {
+	return readIntFile(filepath.Join(rebaseDir, "next")), readIntFile(filepath.Join(rebaseDir, "last"))
+}
+
+// readIntFile reads the integer contained in path, returning 0 if the file
+// doesn't exist or doesn't contain a valid integer.
+func readIntFile(path string) int {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	v, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// isFile reports wether path exists and is a regular file.
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// isDir reports wether path exists and is a directory.
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
@@ -0,0 +1,90 @@
+package gitstatus
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectStateRebaseMerge(t *testing.T) {
+	dir := t.TempDir()
+	rebaseDir := filepath.Join(dir, "rebase-merge")
+	assert.NoError(t, os.MkdirAll(rebaseDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(rebaseDir, "msgnum"), []byte("2\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(rebaseDir, "end"), []byte("7\n"), 0o644))
+
+	st := &Status{}
+	st.detectState(dir)
+
+	assert.True(t, st.IsRebasing)
+	assert.True(t, st.IsRebased)
+	assert.Equal(t, 2, st.RebaseStep)
+	assert.Equal(t, 7, st.RebaseTotal)
+}
+
+func TestDetectStateRebaseApply(t *testing.T) {
+	dir := t.TempDir()
+	rebaseDir := filepath.Join(dir, "rebase-apply")
+	assert.NoError(t, os.MkdirAll(rebaseDir, 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(rebaseDir, "next"), []byte("3\n"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(rebaseDir, "last"), []byte("9\n"), 0o644))
+
+	st := &Status{}
+	st.detectState(dir)
+
+	assert.True(t, st.IsRebasing)
+	assert.Equal(t, 3, st.RebaseStep)
+	assert.Equal(t, 9, st.RebaseTotal)
+}
+
+func TestDetectStateMerge(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "MERGE_HEAD"), []byte("deadbeef\n"), 0o644))
+
+	st := &Status{}
+	st.detectState(dir)
+
+	assert.True(t, st.IsMerging)
+	assert.False(t, st.IsRebasing)
+}
+
+func TestDetectStateCherryPickAndRevert(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "CHERRY_PICK_HEAD"), []byte("deadbeef\n"), 0o644))
+
+	st := &Status{}
+	st.detectState(dir)
+	assert.True(t, st.IsCherryPicking)
+
+	dir2 := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir2, "REVERT_HEAD"), []byte("deadbeef\n"), 0o644))
+
+	st2 := &Status{}
+	st2.detectState(dir2)
+	assert.True(t, st2.IsReverting)
+}
+
+func TestDetectStateBisect(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "BISECT_LOG"), []byte("git bisect start\n"), 0o644))
+
+	st := &Status{}
+	st.detectState(dir)
+
+	assert.True(t, st.IsBisecting)
+}
+
+func TestDetectStateNone(t *testing.T) {
+	dir := t.TempDir()
+
+	st := &Status{}
+	st.detectState(dir)
+
+	assert.False(t, st.IsRebasing)
+	assert.False(t, st.IsMerging)
+	assert.False(t, st.IsCherryPicking)
+	assert.False(t, st.IsReverting)
+	assert.False(t, st.IsBisecting)
+}
@@ -5,11 +5,12 @@ package gitstatus
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
-	"os/exec"
 	"regexp"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"github.com/pkg/errors"
@@ -20,65 +21,105 @@ type Status struct {
 	// TODO: see if once the whole status has been parsed we are still going to
 	// need those NumXXX fields. For example, NumUntracked is simply the length
 	// of the Untracked slice.
-	NumAdded     int // NumAdded is the number of files added to the index.
-	NumDeleted   int // NumDeleted is the number of files deleted from the index.
-	NumUpdated   int // NumUpdated is the number of files updated in index.
-	NumRenamed   int // NumRenamed is the number of files renamed in index.
+	NumAdded     int // NumAdded is the number of files added to the index (staged).
+	NumDeleted   int // NumDeleted is the number of files deleted from the index (staged).
+	NumUpdated   int // NumUpdated is the number of files updated in the index (staged).
+	NumRenamed   int // NumRenamed is the number of files renamed or copied in the index (staged).
 	NumConflicts int // NumConflicts is the number of unmerged files.
 	NumUntracked int // NumUntracked is the number of untracked files.
 
+	NumWorktreeDeleted  int // NumWorktreeDeleted is the number of files deleted in the worktree but not staged.
+	NumWorktreeModified int // NumWorktreeModified is the number of files modified in the worktree but not staged.
+
 	CommitSHA1   string // CommitSHA1 is the SHA1 of current commit (or empty in initial state)
 	LocalBranch  string // LocalBranch is the name of the local branch.
 	RemoteBranch string // RemoteBranch is the name of upstream remote branch (tracking).
 	AheadCount   int    // AheadCount indicates by how many commits the local branch is ahead of its upstream branch.
 	BehindCount  int    // BehindCount indicates by how many commits the local branch is behind its upstream branch.
 
-	IsRebased  bool // IsRebased reports wether a rebase is in progress.
+	// IsRebased reports wether a rebase is in progress.
+	//
+	// Deprecated: use IsRebasing instead.
+	IsRebased  bool
 	IsInitial  bool // IsInitial reports wether the working tree is in its initial state (no commit have been performed yet)
 	IsDetached bool // IsDetached reports wether HEAD is not associated to any branch (detached).
 
+	IsRebasing      bool // IsRebasing reports wether a rebase is in progress.
+	RebaseStep      int  // RebaseStep is the current step of an in-progress rebase, or 0 if not rebasing.
+	RebaseTotal     int  // RebaseTotal is the total number of steps of an in-progress rebase, or 0 if not rebasing.
+	IsMerging       bool // IsMerging reports wether a merge is in progress.
+	IsCherryPicking bool // IsCherryPicking reports wether a cherry-pick is in progress.
+	IsReverting     bool // IsReverting reports wether a revert is in progress.
+	IsBisecting     bool // IsBisecting reports wether a bisect is in progress.
+
 	// Untracked contains the untracked files.
 	//
-	// In paths, the given characters are replaced:
-	//  - \t for TAB
-	//  - \n for LF
-	//  - \\ for backslash.
+	// Paths are unescaped: a path git quoted because it contains a special
+	// character (e.g. a TAB or newline) is returned with its raw bytes, not
+	// the literal quoted, C-escaped string git printed.
 	Untracked []string
 
-	// Untracked contains the ignored files.
+	// Ignored contains the ignored files.
 	//
-	// In paths, the given characters are replaced:
-	//  - \t for TAB
-	//  - \n for LF
+	// Paths are unescaped, see Untracked.
 	Ignored []string
+
+	// Changed contains the ordinary changed entries (porcelain v2 '1' records).
+	Changed []Entry
+
+	// Renamed contains the renamed or copied entries (porcelain v2 '2' records).
+	Renamed []Entry
+
+	// Unmerged contains the unmerged entries (porcelain v2 'u' records).
+	Unmerged []Entry
 }
 
-// New returns the Status of the Git working tree 'dir'.
-func New(dir string) (*Status, error) {
-	cmd := exec.Command("git", "status", "-uall", "--porcelain=2", "--branch", "-z", dir)
-	cmd.Env = append(cmd.Env, "LC_ALL=C")
-	out, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, errors.Wrap(err, "can't run git status")
-	}
+// Entry represents a single changed, renamed/copied or unmerged entry
+// reported by a porcelain v2 status record.
+type Entry struct {
+	// XY is the two-letter status code: the index status followed by the
+	// worktree status.
+	XY string
 
-	err = cmd.Start()
-	if err != nil {
-		return nil, errors.Wrap(err, "can't run git status")
-	}
+	// Submodule is the raw submodule state field, e.g. "N..." when the entry
+	// is not a submodule.
+	Submodule string
 
-	st := &Status{}
-	err = st.parsePorcelain(out)
-	if err != nil {
-		return nil, errors.Wrap(err, "can't parse git status")
-	}
+	// Modes holds the octal file mode fields of the record: <mH> <mI> <mW>
+	// for changed and renamed entries, or <m1> <m2> <m3> <mW> (the stage 1,
+	// 2, 3 and worktree modes) for unmerged entries.
+	Modes []string
 
-	err = cmd.Wait()
-	if err != nil {
-		return nil, errors.Wrap(err, "can't run git status")
-	}
+	// Objects holds the object name (SHA1) fields of the record: <hH> <hI>
+	// for changed and renamed entries, or <h1> <h2> <h3> for unmerged
+	// entries.
+	Objects []string
+
+	// Path is the path of the file, relative to the root of the working
+	// tree.
+	Path string
+
+	// OrigPath is the path the file was renamed or copied from. It is only
+	// set on entries found in Status.Renamed.
+	OrigPath string
+}
+
+// New returns the Status of the Git working tree 'dir'. It uses the default
+// Backend, execBackend, which shells out to the git binary.
+func New(dir string) (*Status, error) {
+	return NewContext(context.Background(), dir)
+}
+
+// NewContext is like New but the scan can be cancelled through ctx.
+func NewContext(ctx context.Context, dir string) (*Status, error) {
+	return execBackend{}.Status(ctx, dir)
+}
 
-	return st, nil
+// NewWithBackend returns the Status of the Git working tree 'dir' as computed
+// by backend. Use this to plug in an alternative Backend, such as
+// GoGitBackend, instead of the default exec-based one.
+func NewWithBackend(dir string, backend Backend) (*Status, error) {
+	return backend.Status(context.Background(), dir)
 }
 
 var (
@@ -144,6 +185,165 @@ func (st *Status) parseHeader(line string) error {
 	return nil
 }
 
+// unquotePath decodes a path as emitted by git's porcelain status output.
+// Even under -z, git wraps a path containing special characters in double
+// quotes and C-escapes it (e.g. "\t" for a literal TAB); a path that isn't
+// quoted is returned unchanged.
+func unquotePath(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			buf.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'a':
+			buf.WriteByte('\a')
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'v':
+			buf.WriteByte('\v')
+		case 't':
+			buf.WriteByte('\t')
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case '"':
+			buf.WriteByte('"')
+		case '\\':
+			buf.WriteByte('\\')
+		default:
+			if s[i] >= '0' && s[i] <= '7' && i+2 < len(s) {
+				if v, err := strconv.ParseUint(s[i:i+3], 8, 8); err == nil {
+					buf.WriteByte(byte(v))
+					i += 2
+					continue
+				}
+			}
+			buf.WriteByte('\\')
+			buf.WriteByte(s[i])
+		}
+	}
+	return buf.String()
+}
+
+// countIndexStatus increments the staged (index-side) counters of st
+// according to a single porcelain v2 index status letter.
+func (st *Status) countIndexStatus(x byte) {
+	switch x {
+	case 'A':
+		st.NumAdded++
+	case 'D':
+		st.NumDeleted++
+	case 'M', 'T':
+		st.NumUpdated++
+	}
+}
+
+// countWorktreeStatus increments the unstaged (worktree-side) counters of st
+// according to a single porcelain v2 worktree status letter.
+func (st *Status) countWorktreeStatus(y byte) {
+	switch y {
+	case 'D':
+		st.NumWorktreeDeleted++
+	case 'M', 'T':
+		st.NumWorktreeModified++
+	}
+}
+
+// parseChanged parses a porcelain v2 '1' (ordinary changed) record and
+// appends the resulting Entry to st.Changed.
+//
+//	1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+func (st *Status) parseChanged(line string) error {
+	fields := strings.SplitN(line, " ", 9)
+	if len(fields) != 9 {
+		return fmt.Errorf("malformed porcelain v2 changed entry: %q", line)
+	}
+
+	xy := fields[1]
+	st.countIndexStatus(xy[0])
+	st.countWorktreeStatus(xy[1])
+
+	st.Changed = append(st.Changed, Entry{
+		XY:        xy,
+		Submodule: fields[2],
+		Modes:     fields[3:6],
+		Objects:   fields[6:8],
+		Path:      unquotePath(fields[8]),
+	})
+	return nil
+}
+
+// parseRenamed parses a porcelain v2 '2' (renamed or copied) record and
+// appends the resulting Entry to st.Renamed. Unlike the other record types,
+// a renamed/copied record's path and origPath are two separate NUL-terminated
+// tokens, so a second token is read from scan.
+//
+//	2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path><NUL><origPath>
+func (st *Status) parseRenamed(line string, scan *bufio.Scanner) error {
+	fields := strings.SplitN(line, " ", 9)
+	if len(fields) != 9 {
+		return fmt.Errorf("malformed porcelain v2 renamed entry: %q", line)
+	}
+
+	scorePath := strings.SplitN(fields[8], " ", 2)
+	if len(scorePath) != 2 {
+		return fmt.Errorf("malformed porcelain v2 renamed entry: %q", line)
+	}
+
+	if !scan.Scan() {
+		return fmt.Errorf("missing origPath for renamed entry: %q", line)
+	}
+
+	xy := fields[1]
+	st.NumRenamed++
+	st.countWorktreeStatus(xy[1])
+
+	st.Renamed = append(st.Renamed, Entry{
+		XY:        xy,
+		Submodule: fields[2],
+		Modes:     fields[3:6],
+		Objects:   fields[6:8],
+		Path:      unquotePath(scorePath[1]),
+		OrigPath:  unquotePath(scan.Text()),
+	})
+	return nil
+}
+
+// parseUnmerged parses a porcelain v2 'u' (unmerged) record and appends the
+// resulting Entry to st.Unmerged.
+//
+//	u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+func (st *Status) parseUnmerged(line string) error {
+	fields := strings.SplitN(line, " ", 11)
+	if len(fields) != 11 {
+		return fmt.Errorf("malformed porcelain v2 unmerged entry: %q", line)
+	}
+
+	st.NumConflicts++
+
+	st.Unmerged = append(st.Unmerged, Entry{
+		XY:        fields[1],
+		Submodule: fields[2],
+		Modes:     fields[3:7],
+		Objects:   fields[7:10],
+		Path:      unquotePath(fields[10]),
+	})
+	return nil
+}
+
 // scanNilBytes is a bufio.SplitFunc function used to tokenize the input with
 // nil bytes. The last byte should always be a nil byte or scanNilBytes returns
 // an error.
@@ -178,17 +378,24 @@ func (st *Status) parsePorcelain(r io.Reader) error {
 			err = st.parseHeader(line)
 		case '1':
 			// 'ordinary' changed entries
+			err = st.parseChanged(line)
 		case '2':
 			// renamed or copied entries
+			err = st.parseRenamed(line, scan)
 		case 'u':
 			// unmerged entries
+			err = st.parseUnmerged(line)
 		case '?':
-			// untracked items
+			// untracked items: "? <path>"
 			if len(line) >= 3 {
-				st.Untracked = append(st.Untracked, line[2:])
+				st.Untracked = append(st.Untracked, unquotePath(line[2:]))
+				st.NumUntracked++
 			}
 		case '!':
-			// ignored items
+			// ignored items: "! <path>"
+			if len(line) >= 3 {
+				st.Ignored = append(st.Ignored, unquotePath(line[2:]))
+			}
 		}
 		if err != nil {
 			return err
@@ -200,4 +407,4 @@ func (st *Status) parsePorcelain(r io.Reader) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -22,7 +22,8 @@ func TestStatusParseHeaders(t *testing.T) {
 		{
 			name: "aligned",
 			out: porcelainNZT(
-				"## master...origin/master",
+				"# branch.head master",
+				"# branch.upstream origin/master",
 			),
 			want: Status{
 				LocalBranch:  "master",
@@ -32,7 +33,7 @@ func TestStatusParseHeaders(t *testing.T) {
 		{
 			name: "no upstream",
 			out: porcelainNZT(
-				"## master",
+				"# branch.head master",
 			),
 			want: Status{
 				LocalBranch:  "master",
@@ -42,7 +43,9 @@ func TestStatusParseHeaders(t *testing.T) {
 		{
 			name: "diverged",
 			out: porcelainNZT(
-				"## feature/123/a...upstream/feature/123/a [ahead 26, behind 2]",
+				"# branch.head feature/123/a",
+				"# branch.upstream upstream/feature/123/a",
+				"# branch.ab +26 -2",
 			),
 			want: Status{
 				LocalBranch:  "feature/123/a",
@@ -54,7 +57,8 @@ func TestStatusParseHeaders(t *testing.T) {
 		{
 			name: "initial",
 			out: porcelainNZT(
-				"## No commits yet on thisbranch",
+				"# branch.oid (initial)",
+				"# branch.head thisbranch",
 			),
 			want: Status{
 				LocalBranch: "thisbranch",
@@ -64,7 +68,7 @@ func TestStatusParseHeaders(t *testing.T) {
 		{
 			name: "detached",
 			out: porcelainNZT(
-				"## HEAD (no branch)",
+				"# branch.head (detached)",
 			),
 			want: Status{
 				IsDetached: true,
@@ -76,98 +80,105 @@ func TestStatusParseHeaders(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			got := &Status{}
 			r := bytes.NewReader(tt.out)
-			_, err := got.ReadFrom(r)
-			assert.Equal(t, err, tt.wantErr)
-			assert.Equal(t, *got, tt.want)
+			err := got.parsePorcelain(r)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, *got)
 		})
 	}
 }
 
-func TestStatusParseModified(t *testing.T) {
+func TestStatusParseChanged(t *testing.T) {
 	tests := []struct {
 		name    string
 		out     []byte // git status output
 		want    Status
-		wantErr error
+		wantErr bool
 	}{
 		{
-			name: "all cases",
+			name: "staged and worktree changes",
 			out: porcelainNZT(
-				"## master",
-				" M index not updated",
-				"MM index updated",
-				"AM added to index",
-				"RM renamed in index",
-				"CM copied in index",
+				"# branch.head master",
+				"1 A. N... 100644 100644 100644 0000000 1111111 added.txt",
+				"1 D. N... 100644 100644 100644 2222222 0000000 deleted.txt",
+				"1 M. N... 100644 100644 100644 3333333 4444444 staged-modified.txt",
+				"1 .M N... 100644 100644 100644 5555555 5555555 worktree-modified.txt",
+				"1 .D N... 100644 100644 000000 6666666 6666666 worktree-deleted.txt",
 			),
 			want: Status{
-				LocalBranch: "master",
-				NumModified: 5,
+				LocalBranch:         "master",
+				NumAdded:            1,
+				NumDeleted:          1,
+				NumUpdated:          1,
+				NumWorktreeModified: 1,
+				NumWorktreeDeleted:  1,
+				Changed: []Entry{
+					{XY: "A.", Submodule: "N...", Modes: []string{"100644", "100644", "100644"}, Objects: []string{"0000000", "1111111"}, Path: "added.txt"},
+					{XY: "D.", Submodule: "N...", Modes: []string{"100644", "100644", "100644"}, Objects: []string{"2222222", "0000000"}, Path: "deleted.txt"},
+					{XY: "M.", Submodule: "N...", Modes: []string{"100644", "100644", "100644"}, Objects: []string{"3333333", "4444444"}, Path: "staged-modified.txt"},
+					{XY: ".M", Submodule: "N...", Modes: []string{"100644", "100644", "100644"}, Objects: []string{"5555555", "5555555"}, Path: "worktree-modified.txt"},
+					{XY: ".D", Submodule: "N...", Modes: []string{"100644", "100644", "000000"}, Objects: []string{"6666666", "6666666"}, Path: "worktree-deleted.txt"},
+				},
 			},
 		},
+		{
+			name: "malformed entry",
+			out: porcelainNZT(
+				"# branch.head master",
+				"1 A. N... 100644",
+			),
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			got := &Status{}
 			r := bytes.NewReader(tt.out)
-			_, err := got.ReadFrom(r)
-			assert.Equal(t, err, tt.wantErr)
-			assert.Equal(t, *got, tt.want)
+			err := got.parsePorcelain(r)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, *got)
 		})
 	}
 }
 
-func TestStatusParseConflicts(t *testing.T) {
+func TestStatusParseRenamed(t *testing.T) {
 	tests := []struct {
-		name    string
-		out     []byte // git status output
-		want    Status
-		wantErr error
+		name string
+		out  []byte // git status output
+		want Status
 	}{
 		{
-			name: "all cases",
+			name: "plain rename",
 			out: porcelainNZT(
-				"## HEAD (no branch)",
-				"UD unmerged, deleted by them",
-				"UA unmerged, added by them",
-				"UU unmerged, both modified",
+				"# branch.head master",
+				"2 R. N... 100644 100644 100644 aaaaaaa bbbbbbb R100 renamed-to.txt",
+				"renamed-from.txt",
 			),
 			want: Status{
-				IsDetached:   true,
-				NumConflicts: 3,
+				LocalBranch: "master",
+				NumRenamed:  1,
+				Renamed: []Entry{
+					{XY: "R.", Submodule: "N...", Modes: []string{"100644", "100644", "100644"}, Objects: []string{"aaaaaaa", "bbbbbbb"}, Path: "renamed-to.txt", OrigPath: "renamed-from.txt"},
+				},
 			},
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := &Status{}
-			r := bytes.NewReader(tt.out)
-			_, err := got.ReadFrom(r)
-			assert.Equal(t, err, tt.wantErr)
-			assert.Equal(t, *got, tt.want)
-		})
-	}
-}
-
-func TestStatusParseUntracked(t *testing.T) {
-	tests := []struct {
-		name    string
-		out     []byte // git status output
-		want    Status
-		wantErr error
-	}{
 		{
-			name: "all cases",
+			name: "quoted copy with worktree modification",
 			out: porcelainNZT(
-				`## HEAD (no branch)`,
-				`?? blabla`,
-				`?? "dir1/dir2/nested with\ttab"`,
-				`?? "dir1/dir2/nested with backslash\\"`,
-				`?? "dir1/dir2/nested with carrier \nreturn"`,
+				"# branch.head master",
+				`2 CM N... 100644 100644 100644 aaaaaaa bbbbbbb C100 "dir/with`+"\\t"+`tab"`,
+				`"dir/with backslash\\"`,
 			),
 			want: Status{
-				IsDetached:   true,
-				NumUntracked: 4,
+				LocalBranch:         "master",
+				NumRenamed:          1,
+				NumWorktreeModified: 1,
+				Renamed: []Entry{
+					{XY: "CM", Submodule: "N...", Modes: []string{"100644", "100644", "100644"}, Objects: []string{"aaaaaaa", "bbbbbbb"}, Path: "dir/with\ttab", OrigPath: `dir/with backslash\`},
+				},
 			},
 		},
 	}
@@ -175,48 +186,139 @@ func TestStatusParseUntracked(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			got := &Status{}
 			r := bytes.NewReader(tt.out)
-			_, err := got.ReadFrom(r)
-			assert.Equal(t, err, tt.wantErr)
-			assert.Equal(t, *got, tt.want)
+			err := got.parsePorcelain(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, *got)
 		})
 	}
 }
 
-func TestStatusParseStaged(t *testing.T) {
-	tests := []struct {
-		name    string
-		out     []byte // git status output
-		want    Status
-		wantErr error
-	}{
-		{
-			name: "all cases",
-			out: porcelainNZT(
-				`## HEAD (no branch)`,
-				`A  dir1/dir2/nested`,
-				`A  "dir1/dir2/nested with\ttab"`,
-				`A  "dir1/dir2/nested with backslash\\"`,
-				`A  "dir1/dir2/nested with carrier \nreturn"`,
-				`M  fileb`,
-				`A  newfile`,
-				`?? untracked`,
-			),
-			want: Status{
-				IsDetached:   true,
-				NumStaged:    6,
-				NumUntracked: 1,
-			},
+func TestStatusParseUnmerged(t *testing.T) {
+	out := porcelainNZT(
+		"# branch.head (detached)",
+		"u UD N... 100644 100644 000000 100644 aaaaaaa bbbbbbb ccccccc unmerged-deleted.txt",
+		"u UA N... 000000 100644 100644 100644 aaaaaaa bbbbbbb ccccccc unmerged-added.txt",
+		"u UU N... 100644 100644 100644 100644 aaaaaaa bbbbbbb ccccccc unmerged-both.txt",
+	)
+	want := Status{
+		IsDetached:   true,
+		NumConflicts: 3,
+		Unmerged: []Entry{
+			{XY: "UD", Submodule: "N...", Modes: []string{"100644", "100644", "000000", "100644"}, Objects: []string{"aaaaaaa", "bbbbbbb", "ccccccc"}, Path: "unmerged-deleted.txt"},
+			{XY: "UA", Submodule: "N...", Modes: []string{"000000", "100644", "100644", "100644"}, Objects: []string{"aaaaaaa", "bbbbbbb", "ccccccc"}, Path: "unmerged-added.txt"},
+			{XY: "UU", Submodule: "N...", Modes: []string{"100644", "100644", "100644", "100644"}, Objects: []string{"aaaaaaa", "bbbbbbb", "ccccccc"}, Path: "unmerged-both.txt"},
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := &Status{}
-			r := bytes.NewReader(tt.out)
-			_, err := got.ReadFrom(r)
-			assert.Equal(t, err, tt.wantErr)
-			assert.Equal(t, *got, tt.want)
-		})
+
+	got := &Status{}
+	err := got.parsePorcelain(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, want, *got)
+}
+
+func TestStatusParseUntracked(t *testing.T) {
+	out := porcelainNZT(
+		`# branch.head (detached)`,
+		`? blabla`,
+		`? a`,
+		`? "dir1/dir2/nested with\ttab"`,
+		`? "dir1/dir2/nested with backslash\\"`,
+		`? "dir1/dir2/nested with carrier \nreturn"`,
+	)
+	want := Status{
+		IsDetached:   true,
+		NumUntracked: 5,
+		Untracked: []string{
+			"blabla",
+			"a",
+			"dir1/dir2/nested with\ttab",
+			"dir1/dir2/nested with backslash\\",
+			"dir1/dir2/nested with carrier \nreturn",
+		},
 	}
+
+	got := &Status{}
+	err := got.parsePorcelain(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, want, *got)
+}
+
+// TestStatusParseUntrackedRealGitOutput guards against the untracked/ignored
+// records using a v1-style double marker ("?? <path>"): real
+// `git status --porcelain=2 -z` (verified against git 2.39.5) emits a single
+// '?'/'!' followed by one space, not two marker characters.
+func TestStatusParseUntrackedRealGitOutput(t *testing.T) {
+	out := porcelainNZT(
+		"# branch.oid ce013625030ba8dba906f756967f9e9ca394464a",
+		"# branch.head master",
+		"? .gitignore",
+		"! app.log",
+	)
+	want := Status{
+		CommitSHA1:   "ce013625030ba8dba906f756967f9e9ca394464a",
+		LocalBranch:  "master",
+		NumUntracked: 1,
+		Untracked:    []string{".gitignore"},
+		Ignored:      []string{"app.log"},
+	}
+
+	got := &Status{}
+	err := got.parsePorcelain(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, want, *got)
+}
+
+func TestStatusParseIgnored(t *testing.T) {
+	out := porcelainNZT(
+		`# branch.head (detached)`,
+		`! blabla.log`,
+		`! "dir1/dir2/nested with\ttab"`,
+	)
+	want := Status{
+		IsDetached: true,
+		Ignored: []string{
+			"blabla.log",
+			"dir1/dir2/nested with\ttab",
+		},
+	}
+
+	got := &Status{}
+	err := got.parsePorcelain(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, want, *got)
+}
+
+func TestStatusParseFull(t *testing.T) {
+	out := porcelainNZT(
+		"# branch.head feature/x",
+		"# branch.upstream origin/feature/x",
+		"# branch.ab +1 -2",
+		"1 A. N... 100644 100644 100644 0000000 1111111 added.txt",
+		"1 .M N... 100644 100644 100644 5555555 5555555 modified.txt",
+		"2 R. N... 100644 100644 100644 aaaaaaa bbbbbbb R100 renamed-to.txt",
+		"renamed-from.txt",
+		"u UU N... 100644 100644 100644 100644 aaaaaaa bbbbbbb ccccccc conflicted.txt",
+		"? untracked.txt",
+		"! ignored.txt",
+	)
+
+	got := &Status{}
+	err := got.parsePorcelain(bytes.NewReader(out))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "feature/x", got.LocalBranch)
+	assert.Equal(t, "origin/feature/x", got.RemoteBranch)
+	assert.Equal(t, 1, got.AheadCount)
+	assert.Equal(t, 2, got.BehindCount)
+	assert.Equal(t, 1, got.NumAdded)
+	assert.Equal(t, 1, got.NumWorktreeModified)
+	assert.Equal(t, 1, got.NumRenamed)
+	assert.Equal(t, 1, got.NumConflicts)
+	assert.Equal(t, 1, got.NumUntracked)
+	assert.Len(t, got.Ignored, 1)
+	assert.Len(t, got.Changed, 2)
+	assert.Len(t, got.Renamed, 1)
+	assert.Len(t, got.Unmerged, 1)
 }
 
 func TestStatusParseMalformed(t *testing.T) {
@@ -233,8 +335,33 @@ func TestStatusParseMalformed(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			got := &Status{}
 			r := bytes.NewReader(tt.out)
-			_, err := got.ReadFrom(r)
+			err := got.parsePorcelain(r)
 			assert.Truef(t, err != nil, "wantErr != nil, got err = %s", err)
 		})
 	}
 }
+
+func TestUnquotePath(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"unquoted", "plain/path.txt", "plain/path.txt"},
+		{"tab", `"a\tb"`, "a\tb"},
+		{"newline", `"a\nb"`, "a\nb"},
+		{"carriage return", `"a\rb"`, "a\rb"},
+		{"quote", `"a\"b"`, `a"b`},
+		{"backslash", `"a\\b"`, `a\b`},
+		{"octal", `"a\302\251b"`, "a\xC2\xA9b"},
+		{"bell", `"a\ab"`, "a\ab"},
+		{"backspace", `"a\bb"`, "a\bb"},
+		{"form feed", `"a\fb"`, "a\fb"},
+		{"vertical tab", `"a\vb"`, "a\vb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, unquotePath(tt.in))
+		})
+	}
+}